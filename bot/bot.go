@@ -0,0 +1,336 @@
+// Package bot implements the IRC connection and the plugin-facing API
+// (command registry and event hooks) that every plugin in this tree is
+// built against.
+package bot
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCommandTimeout bounds how long a command handler may run before
+// its Cmd.Ctx is canceled, so a stuck plugin (e.g. a hung HTTP call) can't
+// wedge the bot forever. Override it with SetCommandTimeout.
+const defaultCommandTimeout = 30 * time.Second
+
+// A CommandFunc handles one invocation of a registered command.
+type CommandFunc func(e *Cmd)
+
+// A Cmd describes one command invocation.
+type Cmd struct {
+	// Bot is the bot the command was invoked on, so handlers can reply or
+	// call other Bot methods.
+	Bot *Bot
+	// Ctx is canceled when the command's timeout elapses or the bot shuts
+	// down, whichever comes first. Handlers that make blocking calls
+	// (HTTP requests, RPCs) should thread it through so they don't keep
+	// running after either happens.
+	Ctx context.Context
+	// Nick, User and Host identify who invoked the command.
+	Nick, User, Host string
+	// Target is the channel the command was sent to, or the bot's own
+	// nick for a private message.
+	Target string
+	// Args is the command text split on whitespace, with the trigger
+	// itself removed.
+	Args []string
+}
+
+// A CommandInfo describes a command registered with Command, as exposed by
+// Commands for introspection (e.g. by plugins/help).
+type CommandInfo struct {
+	Name, Plugin, Desc, Usage string
+}
+
+type command struct {
+	info CommandInfo
+	f    CommandFunc
+}
+
+// A Bot is an IRC connection plus the plugin-facing API: command registry,
+// event hooks, and outgoing actions.
+type Bot struct {
+	host        string
+	ssl         bool
+	nick, ident string
+	channels    []string
+
+	connMu sync.Mutex
+	conn   net.Conn
+	writer *bufio.Writer
+
+	cmdMu      sync.Mutex
+	commands   map[string]*command
+	cmdTimeout time.Duration
+
+	hookMu    sync.Mutex
+	onPrivmsg []func(nick, user, host, target, text string)
+	onJoin    []func(nick, channel string)
+	onPart    []func(nick, channel string)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewBot returns a Bot configured to connect to host (host[:port]), with
+// SSL if ssl is true, using nick and ident, joining channels once
+// connected. Call Run to connect and start processing.
+func NewBot(host string, ssl bool, nick, ident string, channels []string) *Bot {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Bot{
+		host: host, ssl: ssl, nick: nick, ident: ident, channels: channels,
+		commands:   make(map[string]*command),
+		cmdTimeout: defaultCommandTimeout,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// SetCommandTimeout overrides the default per-command timeout applied to
+// Cmd.Ctx. Call it before Run.
+func (b *Bot) SetCommandTimeout(d time.Duration) {
+	b.cmdTimeout = d
+}
+
+// AddCommand registers f to run when a message starting with trigger is
+// seen. Unlike Command, it is not recorded in the Commands() registry, so
+// plugins/help won't describe it; plugin/rpc/server uses this for commands
+// forwarded to out-of-process plugins, whose descriptions live in the
+// plugin process instead.
+func (b *Bot) AddCommand(trigger string, f CommandFunc) {
+	b.register(trigger, CommandInfo{Name: trigger}, f)
+}
+
+// Command registers f to run when a message starting with trigger is seen,
+// and records it in the Commands() registry: plugin names the registering
+// plugin, desc is a one-line summary, and usage shows how to call it.
+func (b *Bot) Command(trigger, plugin, desc, usage string, f CommandFunc) {
+	b.register(trigger, CommandInfo{Name: trigger, Plugin: plugin, Desc: desc, Usage: usage}, f)
+}
+
+func (b *Bot) register(trigger string, info CommandInfo, f CommandFunc) {
+	b.cmdMu.Lock()
+	defer b.cmdMu.Unlock()
+	b.commands[trigger] = &command{info: info, f: f}
+}
+
+// Commands returns the commands registered with Command, for introspection
+// by plugins such as plugins/help. Commands registered with AddCommand are
+// omitted, since they have no plugin/desc/usage to show.
+func (b *Bot) Commands() []CommandInfo {
+	b.cmdMu.Lock()
+	defer b.cmdMu.Unlock()
+	var infos []CommandInfo
+	for _, c := range b.commands {
+		if c.info.Plugin == "" {
+			continue
+		}
+		infos = append(infos, c.info)
+	}
+	return infos
+}
+
+// dispatchCommand looks up the command named by the first field of text
+// and, if registered, runs it in its own goroutine with a Cmd whose Ctx is
+// bounded by b.cmdTimeout and canceled on Close.
+func (b *Bot) dispatchCommand(nick, user, host, target, text string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return
+	}
+	b.cmdMu.Lock()
+	c, ok := b.commands[fields[0]]
+	b.cmdMu.Unlock()
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.ctx, b.cmdTimeout)
+	e := &Cmd{
+		Bot: b, Ctx: ctx,
+		Nick: nick, User: user, Host: host, Target: target,
+		Args: fields[1:],
+	}
+	go func() {
+		defer cancel()
+		c.f(e)
+	}()
+}
+
+// OnPrivmsg registers f to run for every PRIVMSG the bot sees, in addition
+// to any command dispatch.
+func (b *Bot) OnPrivmsg(f func(nick, user, host, target, text string)) {
+	b.hookMu.Lock()
+	defer b.hookMu.Unlock()
+	b.onPrivmsg = append(b.onPrivmsg, f)
+}
+
+// OnJoin registers f to run for every JOIN the bot sees.
+func (b *Bot) OnJoin(f func(nick, channel string)) {
+	b.hookMu.Lock()
+	defer b.hookMu.Unlock()
+	b.onJoin = append(b.onJoin, f)
+}
+
+// OnPart registers f to run for every PART the bot sees.
+func (b *Bot) OnPart(f func(nick, channel string)) {
+	b.hookMu.Lock()
+	defer b.hookMu.Unlock()
+	b.onPart = append(b.onPart, f)
+}
+
+// Privmsg sends an IRC PRIVMSG to target (a channel or nick).
+func (b *Bot) Privmsg(target, text string) { b.send("PRIVMSG %s :%s", target, text) }
+
+// Notice sends an IRC NOTICE to target (a channel or nick).
+func (b *Bot) Notice(target, text string) { b.send("NOTICE %s :%s", target, text) }
+
+// Join makes the bot join channel.
+func (b *Bot) Join(channel string) { b.send("JOIN %s", channel) }
+
+// Kick removes nick from channel, citing reason.
+func (b *Bot) Kick(channel, nick, reason string) { b.send("KICK %s %s :%s", channel, nick, reason) }
+
+// Mode sets an IRC mode on target.
+func (b *Bot) Mode(target, mode string) { b.send("MODE %s %s", target, mode) }
+
+// Run connects to the IRC server, joins the configured channels, and
+// processes incoming lines until the connection drops or Close is called.
+// It blocks; call it last, as the examples do.
+func (b *Bot) Run() error {
+	conn, err := b.dial()
+	if err != nil {
+		return fmt.Errorf("bot: dial %s: %v", b.host, err)
+	}
+	b.connMu.Lock()
+	b.conn = conn
+	b.writer = bufio.NewWriter(conn)
+	b.connMu.Unlock()
+	defer b.Close()
+
+	b.send("NICK %s", b.nick)
+	b.send("USER %s 0 * :%s", b.ident, b.ident)
+	for _, ch := range b.channels {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			b.send("JOIN %s", ch)
+		}
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		b.handleLine(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("bot: read: %v", err)
+	}
+	return nil
+}
+
+// Close disconnects the bot and cancels every in-flight Cmd.Ctx.
+func (b *Bot) Close() {
+	b.cancel()
+	b.connMu.Lock()
+	defer b.connMu.Unlock()
+	if b.conn != nil {
+		b.conn.Close()
+	}
+}
+
+func (b *Bot) dial() (net.Conn, error) {
+	if b.ssl {
+		return tls.Dial("tcp", b.host, nil)
+	}
+	return net.Dial("tcp", b.host)
+}
+
+// send writes one IRC line, serialized against concurrent senders.
+func (b *Bot) send(format string, args ...interface{}) {
+	b.connMu.Lock()
+	defer b.connMu.Unlock()
+	if b.writer == nil {
+		return
+	}
+	fmt.Fprintf(b.writer, format+"\r\n", args...)
+	b.writer.Flush()
+}
+
+func (b *Bot) handleLine(line string) {
+	prefix, cmd, params := parseLine(strings.TrimRight(line, "\r\n"))
+	switch cmd {
+	case "PING":
+		b.send("PONG :%s", strings.Join(params, " "))
+	case "PRIVMSG":
+		if len(params) < 2 {
+			return
+		}
+		nick, user, host := splitPrefix(prefix)
+		target, text := params[0], params[1]
+		b.hookMu.Lock()
+		hooks := append([]func(nick, user, host, target, text string){}, b.onPrivmsg...)
+		b.hookMu.Unlock()
+		for _, f := range hooks {
+			f(nick, user, host, target, text)
+		}
+		b.dispatchCommand(nick, user, host, target, text)
+	case "JOIN":
+		if len(params) < 1 {
+			return
+		}
+		nick, _, _ := splitPrefix(prefix)
+		b.hookMu.Lock()
+		hooks := append([]func(nick, channel string){}, b.onJoin...)
+		b.hookMu.Unlock()
+		for _, f := range hooks {
+			f(nick, strings.TrimPrefix(params[0], ":"))
+		}
+	case "PART":
+		if len(params) < 1 {
+			return
+		}
+		nick, _, _ := splitPrefix(prefix)
+		b.hookMu.Lock()
+		hooks := append([]func(nick, channel string){}, b.onPart...)
+		b.hookMu.Unlock()
+		for _, f := range hooks {
+			f(nick, params[0])
+		}
+	}
+}
+
+// parseLine splits a raw IRC line into its prefix (without the leading
+// ':'), command, and parameters, per RFC 1459 section 2.3.1.
+func parseLine(line string) (prefix, cmd string, params []string) {
+	if strings.HasPrefix(line, ":") {
+		sp := strings.IndexByte(line, ' ')
+		if sp < 0 {
+			return line[1:], "", nil
+		}
+		prefix, line = line[1:sp], line[sp+1:]
+	}
+	if idx := strings.Index(line, " :"); idx >= 0 {
+		params = append(strings.Fields(line[:idx]), line[idx+2:])
+	} else {
+		params = strings.Fields(line)
+	}
+	if len(params) == 0 {
+		return prefix, "", nil
+	}
+	return prefix, params[0], params[1:]
+}
+
+// splitPrefix splits a "nick!user@host" message prefix into its parts.
+// Prefixes that are just a server hostname have no '!'/'@' and are
+// returned as (prefix, "", "").
+func splitPrefix(prefix string) (nick, user, host string) {
+	bang := strings.IndexByte(prefix, '!')
+	at := strings.IndexByte(prefix, '@')
+	if bang < 0 || at < 0 || at < bang {
+		return prefix, "", ""
+	}
+	return prefix[:bang], prefix[bang+1 : at], prefix[at+1:]
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDispatchCommandTimeout(t *testing.T) {
+	b := NewBot("irc.example.com", false, "nick", "ident", nil)
+	b.SetCommandTimeout(20 * time.Millisecond)
+
+	done := make(chan error, 1)
+	b.AddCommand("!wait", func(e *Cmd) {
+		<-e.Ctx.Done()
+		done <- e.Ctx.Err()
+	})
+
+	b.dispatchCommand("nick", "user", "host", "#chan", "!wait")
+
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Ctx.Err() = %v, want DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("command handler never observed its timeout")
+	}
+}
+
+func TestDispatchCommandCanceledOnClose(t *testing.T) {
+	b := NewBot("irc.example.com", false, "nick", "ident", nil)
+	b.SetCommandTimeout(time.Minute)
+
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	b.AddCommand("!wait", func(e *Cmd) {
+		close(started)
+		<-e.Ctx.Done()
+		done <- e.Ctx.Err()
+	})
+
+	b.dispatchCommand("nick", "user", "host", "#chan", "!wait")
+	<-started
+	b.Close()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Ctx.Err() = %v, want Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("command handler never observed bot shutdown")
+	}
+}
+
+func TestCommandsOmitsAddCommand(t *testing.T) {
+	b := NewBot("irc.example.com", false, "nick", "ident", nil)
+	b.Command("!help", "help", "list commands", "!help", func(*Cmd) {})
+	b.AddCommand("!internal", func(*Cmd) {})
+
+	got := b.Commands()
+	if len(got) != 1 || got[0].Name != "!help" {
+		t.Errorf("Commands() = %+v, want only the !help entry", got)
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/StalkR/goircbot/plugins/admin"
 	"github.com/StalkR/goircbot/plugins/dns"
 	"github.com/StalkR/goircbot/plugins/failotron"
+	//"github.com/StalkR/goircbot/plugins/feeds"
 	"github.com/StalkR/goircbot/plugins/geo"
+	"github.com/StalkR/goircbot/plugins/help"
 	"github.com/StalkR/goircbot/plugins/imdb"
 	"github.com/StalkR/goircbot/plugins/ping"
 	"github.com/StalkR/goircbot/plugins/scores"
@@ -17,6 +19,7 @@ import (
 	"github.com/StalkR/goircbot/plugins/sed"
 	//"github.com/StalkR/goircbot/plugins/tail"
 	//"github.com/StalkR/goircbot/plugins/translate"
+	//"github.com/StalkR/goircbot/plugins/transmission"
 	"github.com/StalkR/goircbot/plugins/up"
 	"github.com/StalkR/goircbot/plugins/urban"
 	"github.com/StalkR/goircbot/plugins/urltitle"
@@ -37,7 +40,9 @@ func main() {
 	admin.Register(b, []string{"nick!ident@host"})
 	dns.Register(b)
 	failotron.Register(b, ignore)
+	//feeds.Register(b, "/tmp/feeds.json", 5*time.Minute, 5)
 	geo.Register(b)
+	help.Register(b)
 	imdb.Register(b)
 	ping.Register(b)
 	scores.Register(b, "/tmp/scores")
@@ -45,6 +50,7 @@ func main() {
 	sed.Register(b)
 	//tail.Register(b, []string{"/path/to/file"})
 	//translate.Register(b, "<key>")
+	//transmission.Register(b, transmission.Config{URL: "http://localhost:9091"})
 	up.Register(b)
 	urban.Register(b)
 	urltitle.Register(b, ignore)
@@ -0,0 +1,54 @@
+// Package deadline provides a resettable timer modeled on the
+// SetDeadline/SetReadDeadline pattern used by net.Conn (and netstack's
+// gonet.deadlineTimer), so a long-running loop can react to a changing
+// deadline without leaking goroutines.
+package deadline
+
+import (
+	"sync"
+	"time"
+)
+
+// A Timer fires its channel when its deadline passes. The zero value has no
+// deadline set.
+type Timer struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	expired chan struct{}
+}
+
+// New returns a Timer with no deadline set.
+func New() *Timer {
+	return &Timer{expired: make(chan struct{})}
+}
+
+// C returns the channel that is closed when the current deadline passes.
+// SetDeadline replaces the channel, so callers should re-fetch C after each
+// call to SetDeadline rather than caching it.
+func (d *Timer) C() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.expired
+}
+
+// SetDeadline arms the timer to fire at t, or disarms it if t is the zero
+// time.
+func (d *Timer) SetDeadline(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.expired = make(chan struct{})
+	if t.IsZero() {
+		d.timer = nil
+		return
+	}
+	expired := d.expired
+	d.timer = time.AfterFunc(time.Until(t), func() { close(expired) })
+}
+
+// Stop disarms the timer.
+func (d *Timer) Stop() {
+	d.SetDeadline(time.Time{})
+}
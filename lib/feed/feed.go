@@ -0,0 +1,149 @@
+// Package feed implements a minimal RSS/Atom feed fetcher with
+// conditional-GET support.
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// An Item is a single entry in a feed.
+type Item struct {
+	Title string
+	Link  string
+	GUID  string
+}
+
+// Key returns the identifier used to deduplicate items: the GUID if set,
+// otherwise the link.
+func (i Item) Key() string {
+	if i.GUID != "" {
+		return i.GUID
+	}
+	return i.Link
+}
+
+// A Feed is the parsed result of fetching a feed URL.
+type Feed struct {
+	Title string
+	Items []Item
+
+	// ETag and LastModified are the caching headers returned by the
+	// server, to be passed back into the next call to Fetch.
+	ETag, LastModified string
+
+	// NotModified is true when the server replied 304 Not Modified; Items
+	// is empty in that case and the caller should keep using the
+	// previous result.
+	NotModified bool
+}
+
+// Fetch retrieves and parses the feed at url. etag and lastModified, if
+// non-empty, are sent as conditional-GET headers so unchanged feeds return
+// 304 Not Modified instead of a full body. ctx cancels the request, e.g. on
+// a per-poll deadline.
+func Fetch(ctx context.Context, url, etag, lastModified string) (*Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return &Feed{NotModified: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed: unexpected status %s", resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parse(b)
+	if err != nil {
+		return nil, err
+	}
+	f.ETag = resp.Header.Get("ETag")
+	f.LastModified = resp.Header.Get("Last-Modified")
+	return f, nil
+}
+
+// parse decodes b as either an RSS 2.0 or Atom feed.
+func parse(b []byte) (*Feed, error) {
+	var probe struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(b, &probe); err != nil {
+		return nil, fmt.Errorf("feed: %v", err)
+	}
+	switch probe.XMLName.Local {
+	case "rss":
+		return parseRSS(b)
+	case "feed":
+		return parseAtom(b)
+	default:
+		return nil, fmt.Errorf("feed: unrecognized format %q", probe.XMLName.Local)
+	}
+}
+
+func parseRSS(b []byte) (*Feed, error) {
+	var rss struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Item  []struct {
+				Title string `xml:"title"`
+				Link  string `xml:"link"`
+				GUID  string `xml:"guid"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(b, &rss); err != nil {
+		return nil, fmt.Errorf("feed: rss: %v", err)
+	}
+	f := &Feed{Title: rss.Channel.Title}
+	for _, it := range rss.Channel.Item {
+		f.Items = append(f.Items, Item{Title: it.Title, Link: it.Link, GUID: it.GUID})
+	}
+	return f, nil
+}
+
+func parseAtom(b []byte) (*Feed, error) {
+	var atom struct {
+		Title string `xml:"title"`
+		Entry []struct {
+			Title string `xml:"title"`
+			ID    string `xml:"id"`
+			Link  []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(b, &atom); err != nil {
+		return nil, fmt.Errorf("feed: atom: %v", err)
+	}
+	f := &Feed{Title: atom.Title}
+	for _, e := range atom.Entry {
+		link := ""
+		for _, l := range e.Link {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		f.Items = append(f.Items, Item{Title: e.Title, Link: link, GUID: e.ID})
+	}
+	return f, nil
+}
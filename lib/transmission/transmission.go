@@ -3,6 +3,7 @@ package transmission
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,11 +11,16 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/StalkR/goircbot/lib/tls"
 )
 
+// defaultTimeout is the dial timeout, and the request timeout unless that is
+// overridden with SetTimeout.
+const defaultTimeout = 5 * time.Second
+
 // A Statistics holds generic stats of Transmission.
 type Statistics struct {
 	DownloadSpeed, UploadSpeed                           int
@@ -29,8 +35,14 @@ func (s *Statistics) String() string {
 
 // A Conn represents a connection to Transmission.
 type Conn struct {
-	url    string
-	client http.Client
+	url        string
+	user, pass string
+	client     http.Client
+
+	mu     sync.Mutex
+	sessId string
+
+	stop chan struct{}
 }
 
 func timeoutDialer(d time.Duration) func(net, addr string) (net.Conn, error) {
@@ -41,24 +53,83 @@ func timeoutDialer(d time.Duration) func(net, addr string) (net.Conn, error) {
 
 // New prepares a Transmission connection by returning a *Conn.
 func New(rawurl string) (*Conn, error) {
+	return newConn(rawurl, "", "")
+}
+
+// NewWithAuth prepares a Transmission connection using HTTP basic auth.
+func NewWithAuth(rawurl, user, pass string) (*Conn, error) {
+	return newConn(rawurl, user, pass)
+}
+
+func newConn(rawurl, user, pass string) (*Conn, error) {
 	u, err := url.Parse(rawurl)
 	if err != nil {
 		return nil, err
 	}
 	return &Conn{
-		url: rawurl,
+		url:  rawurl,
+		user: user,
+		pass: pass,
 		client: http.Client{
 			Transport: &http.Transport{
-				Dial:            timeoutDialer(5 * time.Second),
+				Dial:            timeoutDialer(defaultTimeout),
 				TLSClientConfig: tls.Config(u.Host),
 			},
+			Timeout: defaultTimeout,
 		},
 	}, nil
 }
 
+// SetTimeout overrides the HTTP client timeout used for requests, which
+// defaults to 5 seconds.
+func (c *Conn) SetTimeout(d time.Duration) {
+	c.client.Timeout = d
+}
+
+// Keepalive starts a background goroutine that periodically calls Stats to
+// keep the RPC session warm, until Close is called. It is opt-in since most
+// callers issue requests often enough that the session never needs it.
+func (c *Conn) Keepalive(interval time.Duration) {
+	c.mu.Lock()
+	if c.stop != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.stop = make(chan struct{})
+	stop := c.stop
+	c.mu.Unlock()
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				c.Stats(context.Background())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the keepalive goroutine started by Keepalive, if any.
+func (c *Conn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
 // sessionId asks Transmission for an RPC session ID.
-func (c *Conn) sessionId() (string, error) {
-	resp, err := c.client.Get(c.url + "/transmission/rpc")
+func (c *Conn) sessionId(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url+"/transmission/rpc", nil)
+	if err != nil {
+		return "", err
+	}
+	c.setAuth(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -70,9 +141,49 @@ func (c *Conn) sessionId() (string, error) {
 	return values[0], nil
 }
 
-// rpc sends an RPC request to Transmission with the right session ID.
-func (c *Conn) rpc(request interface{}) ([]byte, error) {
-	sessId, err := c.sessionId()
+// setAuth attaches HTTP basic auth credentials to req if configured.
+func (c *Conn) setAuth(req *http.Request) {
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
+}
+
+// cachedSessionId returns the cached session ID, fetching one from
+// Transmission the first time it is needed.
+func (c *Conn) cachedSessionId(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessId != "" {
+		return c.sessId, nil
+	}
+	sessId, err := c.sessionId(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.sessId = sessId
+	return c.sessId, nil
+}
+
+// refreshSessionId discards the cached session ID and fetches a new one,
+// used after Transmission replies 409 Conflict to an out-of-date one.
+func (c *Conn) refreshSessionId(ctx context.Context) (string, error) {
+	sessId, err := c.sessionId(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.sessId = sessId
+	c.mu.Unlock()
+	return sessId, nil
+}
+
+// rpc sends an RPC request to Transmission with the right session ID,
+// transparently refreshing it and retrying once on a 409 Conflict as
+// documented by the Transmission RPC protocol. ctx is attached to every
+// outbound request, so a canceled or expired ctx aborts the call instead of
+// blocking until the TCP timeout.
+func (c *Conn) rpc(ctx context.Context, request interface{}) ([]byte, error) {
+	sessId, err := c.cachedSessionId(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -80,23 +191,49 @@ func (c *Conn) rpc(request interface{}) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", c.url+"/transmission/rpc",
-		bytes.NewBufferString(string(js)))
+	b, status, err := c.do(ctx, js, sessId)
 	if err != nil {
 		return nil, err
 	}
+	if status == http.StatusConflict {
+		sessId, err = c.refreshSessionId(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b, status, err = c.do(ctx, js, sessId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("transmission: unexpected status %d", status)
+	}
+	return b, nil
+}
+
+// do performs a single RPC POST with the given session ID.
+func (c *Conn) do(ctx context.Context, js []byte, sessId string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url+"/transmission/rpc", bytes.NewReader(js))
+	if err != nil {
+		return nil, 0, err
+	}
 	req.Header.Add("X-Transmission-Session-Id", sessId)
+	c.setAuth(req)
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
-	return ioutil.ReadAll(resp.Body)
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return b, resp.StatusCode, nil
 }
 
 // Stats returns current statistics (speed, number of torrents, etc.).
-func (c *Conn) Stats() (*Statistics, error) {
-	b, err := c.rpc(map[string]string{"method": "session-stats"})
+func (c *Conn) Stats(ctx context.Context) (*Statistics, error) {
+	b, err := c.rpc(ctx, map[string]string{"method": "session-stats"})
 	if err != nil {
 		return nil, err
 	}
@@ -116,8 +253,8 @@ type sessionStats struct {
 }
 
 // Add adds a torrent by URL and returns its name.
-func (c *Conn) Add(url string) (string, error) {
-	b, err := c.rpc(map[string]interface{}{
+func (c *Conn) Add(ctx context.Context, url string) (string, error) {
+	b, err := c.rpc(ctx, map[string]interface{}{
 		"method": "torrent-add",
 		"arguments": map[string]interface{}{
 			"paused":   false,
@@ -152,4 +289,109 @@ type torrentAddedArguments struct {
 type torrentAdded struct {
 	Id               int
 	Name, HashString string
-}
\ No newline at end of file
+}
+
+// defaultFields is the set of torrent-get fields returned by List when the
+// caller does not ask for specific ones.
+var defaultFields = []string{"id", "name", "hashString", "status", "percentDone",
+	"rateDownload", "rateUpload", "eta", "totalSize"}
+
+// A Torrent holds the fields of a torrent as returned by List or Get.
+type Torrent struct {
+	Id                       int
+	Name, HashString         string
+	Status                   int
+	PercentDone              float64
+	RateDownload, RateUpload int
+	Eta                      int
+	TotalSize                int64
+	Files                    []File
+}
+
+// A File is a single file within a torrent, as returned by Get when "files"
+// is requested.
+type File struct {
+	Name                   string
+	Length, BytesCompleted int64
+}
+
+// List returns the torrents known to Transmission, restricted to fields if
+// given, or defaultFields otherwise.
+func (c *Conn) List(ctx context.Context, fields ...string) ([]Torrent, error) {
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+	return c.torrentGet(ctx, nil, fields)
+}
+
+// Get returns the torrents identified by ids, including their file list.
+func (c *Conn) Get(ctx context.Context, ids []int) ([]Torrent, error) {
+	fields := append(append([]string{}, defaultFields...), "files")
+	return c.torrentGet(ctx, ids, fields)
+}
+
+func (c *Conn) torrentGet(ctx context.Context, ids []int, fields []string) ([]Torrent, error) {
+	args := map[string]interface{}{"fields": fields}
+	if len(ids) > 0 {
+		args["ids"] = ids
+	}
+	b, err := c.rpc(ctx, map[string]interface{}{
+		"method":    "torrent-get",
+		"arguments": args,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var r torrentGet
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	if r.Result != "success" {
+		return nil, fmt.Errorf("transmission: result: %s", r.Result)
+	}
+	return r.Arguments.Torrents, nil
+}
+
+type torrentGet struct {
+	Arguments struct {
+		Torrents []Torrent `json:"torrents"`
+	}
+	Result string
+}
+
+// Remove removes the torrents identified by ids, optionally deleting their
+// downloaded data.
+func (c *Conn) Remove(ctx context.Context, ids []int, deleteData bool) error {
+	return c.torrentAction(ctx, "torrent-remove", map[string]interface{}{
+		"ids":               ids,
+		"delete-local-data": deleteData,
+	})
+}
+
+// Start resumes the torrents identified by ids.
+func (c *Conn) Start(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrent-start", map[string]interface{}{"ids": ids})
+}
+
+// Stop pauses the torrents identified by ids.
+func (c *Conn) Stop(ctx context.Context, ids []int) error {
+	return c.torrentAction(ctx, "torrent-stop", map[string]interface{}{"ids": ids})
+}
+
+func (c *Conn) torrentAction(ctx context.Context, method string, args map[string]interface{}) error {
+	b, err := c.rpc(ctx, map[string]interface{}{
+		"method":    method,
+		"arguments": args,
+	})
+	if err != nil {
+		return err
+	}
+	var r struct{ Result string }
+	if err := json.Unmarshal(b, &r); err != nil {
+		return err
+	}
+	if r.Result != "success" {
+		return fmt.Errorf("transmission: result: %s", r.Result)
+	}
+	return nil
+}
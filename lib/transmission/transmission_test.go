@@ -0,0 +1,147 @@
+package transmission
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sessionIdHandler replies with a fixed session ID header, as Transmission
+// does for a bare GET (used by sessionId).
+func sessionIdHandler(id string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Transmission-Session-Id", id)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func TestRPCCachesSessionId(t *testing.T) {
+	var sessionIdRequests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			atomic.AddInt32(&sessionIdRequests, 1)
+			sessionIdHandler("sess-1")(w, r)
+			return
+		}
+		if r.Header.Get("X-Transmission-Session-Id") != "sess-1" {
+			t.Errorf("request missing cached session ID, got %q", r.Header.Get("X-Transmission-Session-Id"))
+		}
+		w.Write([]byte(`{"result":"success"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.rpc(context.Background(), map[string]string{"method": "session-stats"}); err != nil {
+			t.Fatalf("rpc #%d: %v", i, err)
+		}
+	}
+	if got := atomic.LoadInt32(&sessionIdRequests); got != 1 {
+		t.Errorf("sessionIdRequests = %d, want 1 (session ID should be cached across calls)", got)
+	}
+}
+
+func TestRPCBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == "GET" {
+			sessionIdHandler("sess-1")(w, r)
+			return
+		}
+		w.Write([]byte(`{"result":"success"}`))
+	}))
+	defer srv.Close()
+
+	c, err := NewWithAuth(srv.URL, "alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.rpc(context.Background(), map[string]string{"method": "session-stats"}); err != nil {
+		t.Fatalf("rpc with valid auth: %v", err)
+	}
+
+	c2, err := NewWithAuth(srv.URL, "alice", "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c2.rpc(context.Background(), map[string]string{"method": "session-stats"}); err == nil {
+		t.Error("rpc with wrong password: got nil error, want one")
+	}
+}
+
+func TestRPCRetriesOn409(t *testing.T) {
+	var sessionIdRequests, posts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			atomic.AddInt32(&sessionIdRequests, 1)
+			sessionIdHandler("sess-fresh")(w, r)
+			return
+		}
+		atomic.AddInt32(&posts, 1)
+		if r.Header.Get("X-Transmission-Session-Id") != "sess-fresh" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.Write([]byte(`{"result":"success"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Prime the cache with a session ID the server will reject, forcing the
+	// 409-retry path on the very first RPC.
+	c.mu.Lock()
+	c.sessId = "stale"
+	c.mu.Unlock()
+
+	if _, err := c.rpc(context.Background(), map[string]string{"method": "session-stats"}); err != nil {
+		t.Fatalf("rpc: %v", err)
+	}
+	if got := atomic.LoadInt32(&posts); got != 2 {
+		t.Errorf("posts = %d, want 2 (one rejected with 409, one retry)", got)
+	}
+	if got := atomic.LoadInt32(&sessionIdRequests); got != 1 {
+		t.Errorf("sessionIdRequests = %d, want 1 (refreshSessionId should fetch exactly once)", got)
+	}
+}
+
+func TestKeepaliveCallsStats(t *testing.T) {
+	called := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			sessionIdHandler("sess-1")(w, r)
+			return
+		}
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+		w.Write([]byte(`{"result":"success","arguments":{}}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.Keepalive(10 * time.Millisecond)
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("keepalive never called Stats")
+	}
+}
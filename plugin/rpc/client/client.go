@@ -0,0 +1,90 @@
+// Package client lets a plugin run as a separate process from the bot,
+// talking to it over the gRPC stream implemented by plugin/rpc/server.
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/StalkR/goircbot/plugin/rpc/rpcpb"
+)
+
+// A Handler reacts to Events sent by the bot. It runs in its own goroutine
+// per event, so it must be safe to call concurrently.
+type Handler func(e *rpcpb.Event, a *Actions)
+
+// Actions lets a Handler ask the bot to perform effects for the Event it
+// was given, addressed to the same stream the Event arrived on.
+type Actions struct {
+	sendMu sync.Mutex // guards Send; concurrent Send on one stream is not safe
+	stream rpcpb.Plugin_StreamClient
+}
+
+func (a *Actions) send(action *rpcpb.Action) {
+	// Errors surface on the next Recv in Register's dispatch loop, so they
+	// are not handled here to keep the Handler signature simple.
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+	a.stream.Send(action)
+}
+
+// Privmsg asks the bot to send an IRC PRIVMSG.
+func (a *Actions) Privmsg(target, text string) {
+	a.send(&rpcpb.Action{Privmsg: &rpcpb.Privmsg{Target: target, Text: text}})
+}
+
+// Notice asks the bot to send an IRC NOTICE.
+func (a *Actions) Notice(target, text string) {
+	a.send(&rpcpb.Action{Notice: &rpcpb.Notice{Target: target, Text: text}})
+}
+
+// Join asks the bot to join an IRC channel.
+func (a *Actions) Join(channel string) {
+	a.send(&rpcpb.Action{Join: &rpcpb.Join{Channel: channel}})
+}
+
+// Kick asks the bot to kick nick from channel.
+func (a *Actions) Kick(channel, nick, reason string) {
+	a.send(&rpcpb.Action{Kick: &rpcpb.Kick{Channel: channel, Nick: nick, Reason: reason}})
+}
+
+// Mode asks the bot to set an IRC mode.
+func (a *Actions) Mode(target, mode string) {
+	a.send(&rpcpb.Action{Mode: &rpcpb.Mode{Target: target, Mode: mode}})
+}
+
+// Register dials the bot's plugin/rpc/server at addr, identifies itself as
+// name, asks for commands to be delivered as Command events, and runs
+// handler for every Event received. It blocks until the stream ends or ctx
+// is canceled.
+func Register(ctx context.Context, addr, name string, commands []string, handler Handler) error {
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("rpc: dial %s: %v", addr, err)
+	}
+	defer conn.Close()
+
+	stream, err := rpcpb.NewPluginClient(conn).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("rpc: stream: %v", err)
+	}
+	if err := stream.Send(&rpcpb.Action{Register: &rpcpb.Register{
+		Name: name, Commands: commands,
+	}}); err != nil {
+		return fmt.Errorf("rpc: register: %v", err)
+	}
+
+	actions := &Actions{stream: stream}
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		go handler(e, actions)
+	}
+}
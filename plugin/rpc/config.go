@@ -0,0 +1,37 @@
+// Package rpc defines the on-disk config used to discover out-of-process
+// plugins at bot startup; see plugin/rpc/server and plugin/rpc/client for
+// the gRPC bridge itself.
+package rpc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// An Endpoint names a plugin process expected to connect to the bot's
+// plugin/rpc/server.
+type Endpoint struct {
+	Name string
+	Addr string
+}
+
+// A Config lists the plugin endpoints a bot expects to serve.
+type Config struct {
+	Endpoints []Endpoint
+	// ListenAddr is the address plugin/rpc/server listens on for incoming
+	// plugin connections.
+	ListenAddr string
+}
+
+// LoadConfig reads a Config from a JSON file at path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,213 @@
+// Package rpcpb holds the protocol types for the plugin gRPC service
+// defined in rpc.proto. These are hand-maintained to match rpc.proto
+// rather than run through protoc-gen-go, so the oneofs below are plain
+// optional message fields rather than generated wrapper types; keep the
+// protobuf tags' field numbers in sync with rpc.proto by hand.
+package rpcpb
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// Event is a bot occurrence delivered to a plugin. Exactly one of the
+// fields below is set.
+type Event struct {
+	Privmsg *Privmsg `protobuf:"bytes,1,opt,name=privmsg,proto3"`
+	Join    *Join    `protobuf:"bytes,2,opt,name=join,proto3"`
+	Part    *Part    `protobuf:"bytes,3,opt,name=part,proto3"`
+	Command *Command `protobuf:"bytes,4,opt,name=command,proto3"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+
+// Privmsg is an IRC PRIVMSG, either to a channel or to the bot directly.
+type Privmsg struct {
+	Nick   string `protobuf:"bytes,1,opt,name=nick,proto3"`
+	User   string `protobuf:"bytes,2,opt,name=user,proto3"`
+	Host   string `protobuf:"bytes,3,opt,name=host,proto3"`
+	Target string `protobuf:"bytes,4,opt,name=target,proto3"`
+	Text   string `protobuf:"bytes,5,opt,name=text,proto3"`
+}
+
+func (m *Privmsg) Reset()         { *m = Privmsg{} }
+func (m *Privmsg) String() string { return proto.CompactTextString(m) }
+func (*Privmsg) ProtoMessage()    {}
+
+// Join is an IRC JOIN.
+type Join struct {
+	Nick    string `protobuf:"bytes,1,opt,name=nick,proto3"`
+	Channel string `protobuf:"bytes,2,opt,name=channel,proto3"`
+}
+
+func (m *Join) Reset()         { *m = Join{} }
+func (m *Join) String() string { return proto.CompactTextString(m) }
+func (*Join) ProtoMessage()    {}
+
+// Part is an IRC PART.
+type Part struct {
+	Nick    string `protobuf:"bytes,1,opt,name=nick,proto3"`
+	Channel string `protobuf:"bytes,2,opt,name=channel,proto3"`
+}
+
+func (m *Part) Reset()         { *m = Part{} }
+func (m *Part) String() string { return proto.CompactTextString(m) }
+func (*Part) ProtoMessage()    {}
+
+// Command is a user invoking one of the commands the plugin registered.
+type Command struct {
+	Name   string   `protobuf:"bytes,1,opt,name=name,proto3"`
+	Nick   string   `protobuf:"bytes,2,opt,name=nick,proto3"`
+	User   string   `protobuf:"bytes,3,opt,name=user,proto3"`
+	Host   string   `protobuf:"bytes,4,opt,name=host,proto3"`
+	Target string   `protobuf:"bytes,5,opt,name=target,proto3"`
+	Args   []string `protobuf:"bytes,6,rep,name=args,proto3"`
+}
+
+func (m *Command) Reset()         { *m = Command{} }
+func (m *Command) String() string { return proto.CompactTextString(m) }
+func (*Command) ProtoMessage()    {}
+
+// Action is an effect a plugin asks the bot to perform. Exactly one of the
+// fields below is set. The first Action on a stream must be Register.
+type Action struct {
+	Register *Register `protobuf:"bytes,1,opt,name=register,proto3"`
+	Privmsg  *Privmsg  `protobuf:"bytes,2,opt,name=privmsg,proto3"`
+	Notice   *Notice   `protobuf:"bytes,3,opt,name=notice,proto3"`
+	Join     *Join     `protobuf:"bytes,4,opt,name=join,proto3"`
+	Kick     *Kick     `protobuf:"bytes,5,opt,name=kick,proto3"`
+	Mode     *Mode     `protobuf:"bytes,6,opt,name=mode,proto3"`
+}
+
+func (m *Action) Reset()         { *m = Action{} }
+func (m *Action) String() string { return proto.CompactTextString(m) }
+func (*Action) ProtoMessage()    {}
+
+// Register names the plugin and the commands it wants delivered as Command
+// events.
+type Register struct {
+	Name     string   `protobuf:"bytes,1,opt,name=name,proto3"`
+	Commands []string `protobuf:"bytes,2,rep,name=commands,proto3"`
+}
+
+func (m *Register) Reset()         { *m = Register{} }
+func (m *Register) String() string { return proto.CompactTextString(m) }
+func (*Register) ProtoMessage()    {}
+
+// Notice is an IRC NOTICE.
+type Notice struct {
+	Target string `protobuf:"bytes,1,opt,name=target,proto3"`
+	Text   string `protobuf:"bytes,2,opt,name=text,proto3"`
+}
+
+func (m *Notice) Reset()         { *m = Notice{} }
+func (m *Notice) String() string { return proto.CompactTextString(m) }
+func (*Notice) ProtoMessage()    {}
+
+// Kick is an IRC KICK.
+type Kick struct {
+	Channel string `protobuf:"bytes,1,opt,name=channel,proto3"`
+	Nick    string `protobuf:"bytes,2,opt,name=nick,proto3"`
+	Reason  string `protobuf:"bytes,3,opt,name=reason,proto3"`
+}
+
+func (m *Kick) Reset()         { *m = Kick{} }
+func (m *Kick) String() string { return proto.CompactTextString(m) }
+func (*Kick) ProtoMessage()    {}
+
+// Mode is an IRC MODE change.
+type Mode struct {
+	Target string `protobuf:"bytes,1,opt,name=target,proto3"`
+	Mode   string `protobuf:"bytes,2,opt,name=mode,proto3"`
+}
+
+func (m *Mode) Reset()         { *m = Mode{} }
+func (m *Mode) String() string { return proto.CompactTextString(m) }
+func (*Mode) ProtoMessage()    {}
+
+// PluginClient is the client API for the Plugin service.
+type PluginClient interface {
+	Stream(ctx context.Context, opts ...grpc.CallOption) (Plugin_StreamClient, error)
+}
+
+type pluginClient struct{ cc *grpc.ClientConn }
+
+// NewPluginClient returns a PluginClient backed by cc.
+func NewPluginClient(cc *grpc.ClientConn) PluginClient { return &pluginClient{cc} }
+
+func (c *pluginClient) Stream(ctx context.Context, opts ...grpc.CallOption) (Plugin_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &pluginServiceDesc.Streams[0], "/rpc.Plugin/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &pluginStreamClient{stream}, nil
+}
+
+// Plugin_StreamClient is the plugin side of the Stream RPC: it sends
+// Actions and receives Events.
+type Plugin_StreamClient interface {
+	Send(*Action) error
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type pluginStreamClient struct{ grpc.ClientStream }
+
+func (s *pluginStreamClient) Send(a *Action) error { return s.ClientStream.SendMsg(a) }
+func (s *pluginStreamClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PluginServer is the server API for the Plugin service, implemented by
+// plugin/rpc/server.
+type PluginServer interface {
+	Stream(Plugin_StreamServer) error
+}
+
+// Plugin_StreamServer is the bot side of the Stream RPC: it receives
+// Actions and sends Events.
+type Plugin_StreamServer interface {
+	Send(*Event) error
+	Recv() (*Action, error)
+	grpc.ServerStream
+}
+
+type pluginStreamServer struct{ grpc.ServerStream }
+
+func (s *pluginStreamServer) Send(e *Event) error { return s.ServerStream.SendMsg(e) }
+func (s *pluginStreamServer) Recv() (*Action, error) {
+	m := new(Action)
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RegisterPluginServer registers srv with s so incoming Stream RPCs are
+// dispatched to it.
+func RegisterPluginServer(s *grpc.Server, srv PluginServer) {
+	s.RegisterService(&pluginServiceDesc, srv)
+}
+
+var pluginServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rpc.Plugin",
+	HandlerType: (*PluginServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "Stream",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				return srv.(PluginServer).Stream(&pluginStreamServer{stream})
+			},
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
@@ -0,0 +1,48 @@
+package rpcpb
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// TestEventRoundTrip guards against the Event/Action oneof fields losing
+// their protobuf struct tags again: without them, Marshal silently returns
+// zero bytes instead of an error.
+func TestEventRoundTrip(t *testing.T) {
+	want := &Event{Privmsg: &Privmsg{Nick: "n", User: "u", Host: "h", Target: "#chan", Text: "hi"}}
+	b, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if len(b) == 0 {
+		t.Fatal("Marshal returned zero bytes")
+	}
+
+	got := new(Event)
+	if err := proto.Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Privmsg == nil || *got.Privmsg != *want.Privmsg {
+		t.Errorf("Unmarshal = %+v, want %+v", got.Privmsg, want.Privmsg)
+	}
+}
+
+func TestActionRoundTrip(t *testing.T) {
+	want := &Action{Kick: &Kick{Channel: "#chan", Nick: "n", Reason: "bye"}}
+	b, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if len(b) == 0 {
+		t.Fatal("Marshal returned zero bytes")
+	}
+
+	got := new(Action)
+	if err := proto.Unmarshal(b, got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Kick == nil || *got.Kick != *want.Kick {
+		t.Errorf("Unmarshal = %+v, want %+v", got.Kick, want.Kick)
+	}
+}
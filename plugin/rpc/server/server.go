@@ -0,0 +1,180 @@
+// Package server embeds a gRPC server into bot.Bot so out-of-process
+// plugins can connect, register the commands they handle, and exchange
+// Events/Actions with the bot over a single bidirectional stream.
+package server
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/StalkR/goircbot/bot"
+	"github.com/StalkR/goircbot/plugin/rpc"
+	"github.com/StalkR/goircbot/plugin/rpc/rpcpb"
+)
+
+// A Server bridges bot.Bot events/commands to connected plugin processes.
+type Server struct {
+	bot        *bot.Bot
+	cfg        *rpc.Config
+	grpcServer *grpc.Server
+
+	mu      sync.Mutex
+	clients map[string]*client // by plugin name
+}
+
+type client struct {
+	name string
+
+	sendMu sync.Mutex // guards Send; concurrent Send on one stream is not safe
+	stream rpcpb.Plugin_StreamServer
+}
+
+// New returns a Server that bridges b to the plugin processes listed in
+// cfg, connecting over gRPC.
+func New(b *bot.Bot, cfg *rpc.Config) *Server {
+	return &Server{bot: b, cfg: cfg, clients: make(map[string]*client)}
+}
+
+// ListenAndServe starts the gRPC server on cfg.ListenAddr. It blocks until
+// the listener fails or Stop is called; closing the bot does not stop it.
+func (s *Server) ListenAndServe() error {
+	lis, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("rpc: listen: %v", err)
+	}
+	s.grpcServer = grpc.NewServer()
+	rpcpb.RegisterPluginServer(s.grpcServer, s)
+	s.bot.OnPrivmsg(s.broadcastPrivmsg)
+	s.bot.OnJoin(s.broadcastJoin)
+	s.bot.OnPart(s.broadcastPart)
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop stops accepting plugin connections.
+func (s *Server) Stop() {
+	if s.grpcServer != nil {
+		s.grpcServer.GracefulStop()
+	}
+}
+
+// Stream implements rpcpb.PluginServer. The first Action received must be a
+// Register, naming the plugin and the commands it wants delivered as
+// Command events; every Action after that is applied as a bot action.
+func (s *Server) Stream(stream rpcpb.Plugin_StreamServer) error {
+	action, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	reg := action.Register
+	if reg == nil {
+		return fmt.Errorf("rpc: first message on stream must be Register")
+	}
+	if !s.knownEndpoint(reg.Name) {
+		return fmt.Errorf("rpc: unknown plugin %q", reg.Name)
+	}
+	c := &client{name: reg.Name, stream: stream}
+	s.addClient(c)
+	defer s.removeClient(c)
+
+	for _, name := range reg.Commands {
+		name := name
+		s.bot.AddCommand(name, func(e *bot.Cmd) {
+			s.send(c, &rpcpb.Event{Command: &rpcpb.Command{
+				Name: name, Nick: e.Nick, User: e.User, Host: e.Host,
+				Target: e.Target, Args: e.Args,
+			}})
+		})
+	}
+
+	for {
+		action, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		s.apply(action)
+	}
+}
+
+// knownEndpoint reports whether name is listed in s.cfg.Endpoints. An empty
+// endpoint list accepts any plugin name.
+func (s *Server) knownEndpoint(name string) bool {
+	if len(s.cfg.Endpoints) == 0 {
+		return true
+	}
+	for _, e := range s.cfg.Endpoints {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) addClient(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c.name] = c
+}
+
+func (s *Server) removeClient(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[c.name] == c {
+		delete(s.clients, c.name)
+	}
+}
+
+// send delivers e to c, logging (rather than failing the whole bot) if the
+// plugin has gone away.
+func (s *Server) send(c *client, e *rpcpb.Event) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if err := c.stream.Send(e); err != nil {
+		log.Printf("rpc: sending to plugin %q: %v", c.name, err)
+	}
+}
+
+func (s *Server) broadcast(e *rpcpb.Event) {
+	s.mu.Lock()
+	clients := make([]*client, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+	for _, c := range clients {
+		s.send(c, e)
+	}
+}
+
+func (s *Server) broadcastPrivmsg(nick, user, host, target, text string) {
+	s.broadcast(&rpcpb.Event{Privmsg: &rpcpb.Privmsg{
+		Nick: nick, User: user, Host: host, Target: target, Text: text,
+	}})
+}
+
+func (s *Server) broadcastJoin(nick, channel string) {
+	s.broadcast(&rpcpb.Event{Join: &rpcpb.Join{Nick: nick, Channel: channel}})
+}
+
+func (s *Server) broadcastPart(nick, channel string) {
+	s.broadcast(&rpcpb.Event{Part: &rpcpb.Part{Nick: nick, Channel: channel}})
+}
+
+// apply performs the bot-side effect of an Action received from a plugin.
+func (s *Server) apply(a *rpcpb.Action) {
+	switch {
+	case a.Privmsg != nil:
+		s.bot.Privmsg(a.Privmsg.Target, a.Privmsg.Text)
+	case a.Notice != nil:
+		s.bot.Notice(a.Notice.Target, a.Notice.Text)
+	case a.Join != nil:
+		s.bot.Join(a.Join.Channel)
+	case a.Kick != nil:
+		s.bot.Kick(a.Kick.Channel, a.Kick.Nick, a.Kick.Reason)
+	case a.Mode != nil:
+		s.bot.Mode(a.Mode.Target, a.Mode.Mode)
+	}
+}
@@ -0,0 +1,66 @@
+// Package admin implements an access control list for other plugins that
+// need to gate destructive or sensitive commands to trusted users.
+package admin
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/StalkR/goircbot/bot"
+)
+
+// A list holds the hostmasks (nick!user@host, "*" wildcards allowed) that
+// are considered administrators.
+type list struct {
+	hostmasks []string
+}
+
+// lists maps each registered bot to its own admin list, so two bots in the
+// same process (tests, or a multi-network bot) don't share or clobber each
+// other's ACL the way a single package-level list would.
+var (
+	listsMu sync.Mutex
+	lists   = make(map[*bot.Bot]*list)
+)
+
+// Register registers the admin plugin on b with hostmasks as the list of
+// administrators, each in nick!user@host form.
+func Register(b *bot.Bot, hostmasks []string) {
+	listsMu.Lock()
+	defer listsMu.Unlock()
+	lists[b] = &list{hostmasks: hostmasks}
+}
+
+// IsAdmin reports whether e was sent by a hostmask in its bot's admin list.
+// Other plugins call this to gate destructive commands. It returns false
+// if the admin plugin has not been registered on e.Bot.
+func IsAdmin(e *bot.Cmd) bool {
+	listsMu.Lock()
+	l, ok := lists[e.Bot]
+	listsMu.Unlock()
+	if !ok {
+		return false
+	}
+	who := e.Nick + "!" + e.User + "@" + e.Host
+	for _, mask := range l.hostmasks {
+		if match(mask, who) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether hostmask matches who, treating "*" in hostmask as
+// a wildcard matching any run of characters (including none). The pieces
+// between wildcards are anchored to the start and end of who, so e.g. "*a"
+// only matches strings actually ending in "a".
+func match(hostmask, who string) bool {
+	parts := strings.Split(hostmask, "*")
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = regexp.QuoteMeta(part)
+	}
+	re := regexp.MustCompile("^" + strings.Join(quoted, ".*") + "$")
+	return re.MatchString(who)
+}
@@ -0,0 +1,32 @@
+package admin
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		hostmask, who string
+		want          bool
+	}{
+		{"nick!user@host", "nick!user@host", true},
+		{"nick!user@host", "other!user@host", false},
+		{"*!user@host", "nick!user@host", true},
+		{"nick!*@host", "nick!user@host", true},
+		{"nick!user@*", "nick!user@anything", true},
+		{"*", "anything", true},
+		{"a*", "aaa", true},
+		{"*a", "aaa", true},
+		{"a*a", "aaa", true},
+		{"a*a", "aa", true},
+		// Regression: "aa" is a prefix of "aaa" but must not match without
+		// a trailing wildcard to account for the rest of who.
+		{"aa", "aaa", false},
+		{"a*a", "aab", false},
+		{"nick!user@host", "nick!user@hostx", false},
+		{"nick!user@host", "xnick!user@host", false},
+	}
+	for _, tc := range tests {
+		if got := match(tc.hostmask, tc.who); got != tc.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tc.hostmask, tc.who, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// Package feeds implements an IRC plugin that lets channels subscribe to
+// RSS/Atom feeds and announces new items as they appear.
+package feeds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/StalkR/goircbot/bot"
+	"github.com/StalkR/goircbot/lib/deadline"
+	"github.com/StalkR/goircbot/lib/feed"
+	"github.com/StalkR/goircbot/plugins/admin"
+)
+
+// maxSeen is the number of item keys remembered per subscription, enough to
+// dedup across a handful of poll cycles without the file growing forever.
+const maxSeen = 200
+
+// maxLineLen truncates announced items so a single entry never blows past a
+// reasonable IRC line length.
+const maxLineLen = 300
+
+// fetchTimeout bounds a single feed fetch, so one slow or hanging server
+// can't stall the whole poll cycle.
+const fetchTimeout = 30 * time.Second
+
+// A subscription ties one feed URL to one channel.
+type subscription struct {
+	Channel            string
+	URL                string
+	ETag, LastModified string
+	Seen               []string
+
+	seenSet map[string]bool
+}
+
+type plugin struct {
+	path     string
+	interval time.Duration
+	rate     int // max announcement lines per minute per channel
+
+	mu   sync.Mutex
+	subs []*subscription
+
+	limiters map[string]*rateLimiter
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Register registers the feeds plugin. Subscriptions are persisted as JSON
+// to path. Feeds are polled every interval; rate caps announcements to at
+// most rate lines per minute per channel. Polling is not tied to b: it
+// keeps running after b shuts down, for the life of the process.
+func Register(b *bot.Bot, path string, interval time.Duration, rate int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &plugin{
+		path:     path,
+		interval: interval,
+		rate:     rate,
+		limiters: make(map[string]*rateLimiter),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	if err := p.load(); err != nil {
+		return err
+	}
+	b.Command("!feed", pluginName, "manage RSS/Atom subscriptions for this channel",
+		"!feed add|del|list|force ...", p.cmdFeed(b))
+	p.startPolling(b)
+	return nil
+}
+
+// pluginName identifies this plugin's commands to the help plugin.
+const pluginName = "feeds"
+
+func (p *plugin) cmdFeed(b *bot.Bot) bot.CommandFunc {
+	return func(e *bot.Cmd) {
+		if len(e.Args) == 0 {
+			e.Bot.Privmsg(e.Target, "usage: !feed add|del|list|force ...")
+			return
+		}
+		switch e.Args[0] {
+		case "add":
+			p.cmdAdd(e)
+		case "del":
+			p.cmdDel(e)
+		case "list":
+			p.cmdList(e)
+		case "force":
+			p.force(b)
+			e.Bot.Privmsg(e.Target, "polled all feeds")
+		default:
+			e.Bot.Privmsg(e.Target, "usage: !feed add|del|list|force ...")
+		}
+	}
+}
+
+func (p *plugin) cmdAdd(e *bot.Cmd) {
+	if !admin.IsAdmin(e) {
+		e.Bot.Privmsg(e.Target, "you are not allowed to do that")
+		return
+	}
+	if len(e.Args) < 2 {
+		e.Bot.Privmsg(e.Target, "usage: !feed add <url>")
+		return
+	}
+	url := e.Args[1]
+	p.mu.Lock()
+	p.subs = append(p.subs, &subscription{Channel: e.Target, URL: url, seenSet: make(map[string]bool)})
+	err := p.save()
+	p.mu.Unlock()
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "error saving: "+err.Error())
+		return
+	}
+	e.Bot.Privmsg(e.Target, "subscribed to "+url)
+}
+
+func (p *plugin) cmdDel(e *bot.Cmd) {
+	if !admin.IsAdmin(e) {
+		e.Bot.Privmsg(e.Target, "you are not allowed to do that")
+		return
+	}
+	if len(e.Args) < 2 {
+		e.Bot.Privmsg(e.Target, "usage: !feed del <n>")
+		return
+	}
+	n, err := strconv.Atoi(e.Args[1])
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "usage: !feed del <n>")
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	channelSubs := p.channelSubs(e.Target)
+	if n < 0 || n >= len(channelSubs) {
+		e.Bot.Privmsg(e.Target, "no such subscription")
+		return
+	}
+	target := channelSubs[n]
+	for i, s := range p.subs {
+		if s == target {
+			p.subs = append(p.subs[:i], p.subs[i+1:]...)
+			break
+		}
+	}
+	if err := p.save(); err != nil {
+		e.Bot.Privmsg(e.Target, "error saving: "+err.Error())
+		return
+	}
+	e.Bot.Privmsg(e.Target, "unsubscribed from "+target.URL)
+}
+
+func (p *plugin) cmdList(e *bot.Cmd) {
+	p.mu.Lock()
+	subs := p.channelSubs(e.Target)
+	p.mu.Unlock()
+	if len(subs) == 0 {
+		e.Bot.Privmsg(e.Target, "no subscriptions")
+		return
+	}
+	for i, s := range subs {
+		e.Bot.Privmsg(e.Target, fmt.Sprintf("[%d] %s", i, s.URL))
+	}
+}
+
+// channelSubs returns the subscriptions for channel, in registration order.
+// Caller must hold p.mu.
+func (p *plugin) channelSubs(channel string) []*subscription {
+	var subs []*subscription
+	for _, s := range p.subs {
+		if s.Channel == channel {
+			subs = append(subs, s)
+		}
+	}
+	return subs
+}
+
+// startPolling launches the background polling loop, using a deadline.Timer
+// rather than a time.Ticker so the wait can be interrupted the moment p.ctx
+// is canceled instead of leaking the goroutine until the next tick.
+func (p *plugin) startPolling(b *bot.Bot) {
+	go func() {
+		t := deadline.New()
+		defer t.Stop()
+		for {
+			t.SetDeadline(time.Now().Add(p.interval))
+			select {
+			case <-t.C():
+				p.pollAll(b)
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the polling goroutine and cancels any in-flight fetch.
+func (p *plugin) Stop() {
+	p.cancel()
+}
+
+func (p *plugin) force(b *bot.Bot) {
+	p.pollAll(b)
+}
+
+func (p *plugin) pollAll(b *bot.Bot) {
+	p.mu.Lock()
+	subs := append([]*subscription{}, p.subs...)
+	p.mu.Unlock()
+	for _, s := range subs {
+		p.poll(b, s)
+	}
+	p.mu.Lock()
+	p.save()
+	p.mu.Unlock()
+}
+
+func (p *plugin) poll(b *bot.Bot, s *subscription) {
+	ctx, cancel := context.WithTimeout(p.ctx, fetchTimeout)
+	defer cancel()
+	f, err := feed.Fetch(ctx, s.URL, s.ETag, s.LastModified)
+	if err != nil {
+		return
+	}
+	if f.NotModified {
+		return
+	}
+	p.mu.Lock()
+	s.ETag, s.LastModified = f.ETag, f.LastModified
+	if s.seenSet == nil {
+		s.seenSet = make(map[string]bool)
+		for _, k := range s.Seen {
+			s.seenSet[k] = true
+		}
+	}
+	firstPoll := len(s.seenSet) == 0
+	var fresh []feed.Item
+	for _, item := range f.Items {
+		key := item.Key()
+		if s.seenSet[key] {
+			continue
+		}
+		s.seenSet[key] = true
+		s.Seen = append(s.Seen, key)
+		fresh = append(fresh, item)
+	}
+	if len(s.Seen) > maxSeen {
+		s.Seen = s.Seen[len(s.Seen)-maxSeen:]
+	}
+	channel := s.Channel
+	p.mu.Unlock()
+
+	// Don't spam a channel with a feed's entire backlog the first time it
+	// is polled; only announce items seen from then on.
+	if firstPoll {
+		return
+	}
+	limiter := p.limiterFor(channel)
+	for _, item := range fresh {
+		if !limiter.Allow() {
+			return
+		}
+		b.Privmsg(channel, announcement(f.Title, item.Title, item.Link))
+	}
+}
+
+func (p *plugin) limiterFor(channel string) *rateLimiter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l, ok := p.limiters[channel]
+	if !ok {
+		l = newRateLimiter(p.rate, time.Minute)
+		p.limiters[channel] = l
+	}
+	return l
+}
+
+// announcement formats a feed item announcement as "feedTitle: itemTitle
+// link", truncating itemTitle (never link) so the line fits maxLineLen
+// without chopping the URL.
+func announcement(feedTitle, itemTitle, link string) string {
+	s := fmt.Sprintf("%s: %s %s", feedTitle, itemTitle, link)
+	if len(s) <= maxLineLen {
+		return s
+	}
+	budget := len(itemTitle) - (len(s) - maxLineLen)
+	if budget < 0 {
+		budget = 0
+	}
+	for budget > 0 && !utf8.RuneStart(itemTitle[budget]) {
+		budget--
+	}
+	return fmt.Sprintf("%s: %s %s", feedTitle, itemTitle[:budget], link)
+}
+
+// load reads subscriptions from p.path, if it exists.
+func (p *plugin) load() error {
+	b, err := ioutil.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var subs []*subscription
+	if err := json.Unmarshal(b, &subs); err != nil {
+		return fmt.Errorf("feeds: %v", err)
+	}
+	for _, s := range subs {
+		s.seenSet = make(map[string]bool)
+		for _, k := range s.Seen {
+			s.seenSet[k] = true
+		}
+	}
+	p.subs = subs
+	return nil
+}
+
+// save writes subscriptions to p.path. Caller must hold p.mu.
+func (p *plugin) save() error {
+	b, err := json.MarshalIndent(p.subs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.path, b, 0644)
+}
+
+// rateLimiter caps the number of events allowed within a rolling window.
+type rateLimiter struct {
+	mu     sync.Mutex
+	max    int
+	window time.Duration
+	events []time.Time
+	now    func() time.Time
+}
+
+func newRateLimiter(max int, window time.Duration) *rateLimiter {
+	return &rateLimiter{max: max, window: window, now: time.Now}
+}
+
+// Allow reports whether another event may proceed without exceeding max
+// events per window.
+func (r *rateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	cutoff := now.Add(-r.window)
+	i := 0
+	for ; i < len(r.events); i++ {
+		if r.events[i].After(cutoff) {
+			break
+		}
+	}
+	r.events = r.events[i:]
+	if len(r.events) >= r.max {
+		return false
+	}
+	r.events = append(r.events, now)
+	return true
+}
@@ -0,0 +1,59 @@
+// Package help implements a !help command that introspects the bot's
+// command registry.
+package help
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/StalkR/goircbot/bot"
+)
+
+// pluginName identifies this plugin's own command to itself.
+const pluginName = "help"
+
+// Register registers the help plugin.
+func Register(b *bot.Bot) {
+	b.Command("!help", pluginName, "list commands, or show usage for one",
+		"!help [command]", cmdHelp(b))
+}
+
+func cmdHelp(b *bot.Bot) bot.CommandFunc {
+	return func(e *bot.Cmd) {
+		if len(e.Args) == 0 {
+			listCommands(b, e)
+			return
+		}
+		describeCommand(b, e, e.Args[0])
+	}
+}
+
+// listCommands sends a NOTICE per plugin listing its commands.
+func listCommands(b *bot.Bot, e *bot.Cmd) {
+	byPlugin := make(map[string][]string)
+	for _, c := range b.Commands() {
+		byPlugin[c.Plugin] = append(byPlugin[c.Plugin], c.Name)
+	}
+	plugins := make([]string, 0, len(byPlugin))
+	for p := range byPlugin {
+		plugins = append(plugins, p)
+	}
+	sort.Strings(plugins)
+	for _, p := range plugins {
+		names := byPlugin[p]
+		sort.Strings(names)
+		e.Bot.Notice(e.Nick, fmt.Sprintf("%s: %s", p, strings.Join(names, " ")))
+	}
+}
+
+// describeCommand sends a NOTICE with the usage and description of name.
+func describeCommand(b *bot.Bot, e *bot.Cmd, name string) {
+	for _, c := range b.Commands() {
+		if c.Name == name {
+			e.Bot.Notice(e.Nick, fmt.Sprintf("%s: %s (%s)", c.Usage, c.Desc, c.Plugin))
+			return
+		}
+	}
+	e.Bot.Notice(e.Nick, "no such command: "+name)
+}
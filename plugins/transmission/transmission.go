@@ -0,0 +1,243 @@
+// Package transmission implements an IRC plugin to list and control
+// torrents on a Transmission daemon.
+package transmission
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/StalkR/goircbot/bot"
+	"github.com/StalkR/goircbot/lib/transmission"
+	"github.com/StalkR/goircbot/plugins/admin"
+)
+
+// maxLineLen is the maximum length of a single IRC line of output; longer
+// listings are split across several lines.
+const maxLineLen = 400
+
+// A Config configures the transmission plugin.
+type Config struct {
+	// URL is the Transmission RPC endpoint, e.g. "http://localhost:9091".
+	URL string
+	// User and Pass enable HTTP basic auth if non-empty.
+	User, Pass string
+	// Channels restricts the plugin to the given channels; if empty the
+	// plugin is enabled everywhere.
+	Channels []string
+}
+
+type plugin struct {
+	conn *transmission.Conn
+	cfg  Config
+}
+
+// Register registers the transmission plugin with the given config.
+func Register(b *bot.Bot, cfg Config) error {
+	var conn *transmission.Conn
+	var err error
+	if cfg.User != "" {
+		conn, err = transmission.NewWithAuth(cfg.URL, cfg.User, cfg.Pass)
+	} else {
+		conn, err = transmission.New(cfg.URL)
+	}
+	if err != nil {
+		return fmt.Errorf("transmission: %v", err)
+	}
+	p := &plugin{conn: conn, cfg: cfg}
+	b.Command("!torrents", pluginName, "list torrents", "!torrents", p.enabled(p.cmdTorrents))
+	b.Command("!add", pluginName, "add a torrent by URL or magnet link", "!add <url|magnet>", p.enabled(p.cmdAdd))
+	b.Command("!rm", pluginName, "remove a torrent (admin only)", "!rm <id> [id...]", p.enabled(p.admin(p.cmdRemove)))
+	b.Command("!pause", pluginName, "pause a torrent (admin only)", "!pause <id>", p.enabled(p.admin(p.cmdPause)))
+	b.Command("!resume", pluginName, "resume a torrent (admin only)", "!resume <id>", p.enabled(p.admin(p.cmdResume)))
+	b.Command("!stats", pluginName, "show Transmission speed and torrent counts", "!stats", p.enabled(p.cmdStats))
+	b.Command("!files", pluginName, "list the files of a torrent", "!files <id>", p.enabled(p.cmdFiles))
+	return nil
+}
+
+// pluginName identifies this plugin's commands to the help plugin.
+const pluginName = "transmission"
+
+// enabled wraps f so it is a no-op outside of the configured channels.
+func (p *plugin) enabled(f bot.CommandFunc) bot.CommandFunc {
+	if len(p.cfg.Channels) == 0 {
+		return f
+	}
+	return func(e *bot.Cmd) {
+		for _, c := range p.cfg.Channels {
+			if c == e.Target {
+				f(e)
+				return
+			}
+		}
+	}
+}
+
+// admin wraps f so it only runs for callers in the admin plugin's ACL,
+// since f performs a destructive Transmission action.
+func (p *plugin) admin(f bot.CommandFunc) bot.CommandFunc {
+	return func(e *bot.Cmd) {
+		if !admin.IsAdmin(e) {
+			e.Bot.Privmsg(e.Target, "you are not allowed to do that")
+			return
+		}
+		f(e)
+	}
+}
+
+func (p *plugin) cmdTorrents(e *bot.Cmd) {
+	torrents, err := p.conn.List(e.Ctx)
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "error: "+err.Error())
+		return
+	}
+	if len(torrents) == 0 {
+		e.Bot.Privmsg(e.Target, "no torrents")
+		return
+	}
+	lines := make([]string, 0, len(torrents))
+	for _, t := range torrents {
+		lines = append(lines, fmt.Sprintf("[%d] %s %.0f%% %s", t.Id, t.Name,
+			t.PercentDone*100, statusString(t.Status)))
+	}
+	paginate(e, lines)
+}
+
+func (p *plugin) cmdAdd(e *bot.Cmd) {
+	if len(e.Args) == 0 {
+		e.Bot.Privmsg(e.Target, "usage: !add <url|magnet>")
+		return
+	}
+	name, err := p.conn.Add(e.Ctx, e.Args[0])
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "error: "+err.Error())
+		return
+	}
+	e.Bot.Privmsg(e.Target, "added: "+name)
+}
+
+func (p *plugin) cmdRemove(e *bot.Cmd) {
+	ids, err := parseIds(e.Args)
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "usage: !rm <id> [id...]")
+		return
+	}
+	if err := p.conn.Remove(e.Ctx, ids, false); err != nil {
+		e.Bot.Privmsg(e.Target, "error: "+err.Error())
+		return
+	}
+	e.Bot.Privmsg(e.Target, "removed")
+}
+
+func (p *plugin) cmdPause(e *bot.Cmd) {
+	ids, err := parseIds(e.Args)
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "usage: !pause <id>")
+		return
+	}
+	if err := p.conn.Stop(e.Ctx, ids); err != nil {
+		e.Bot.Privmsg(e.Target, "error: "+err.Error())
+		return
+	}
+	e.Bot.Privmsg(e.Target, "paused")
+}
+
+func (p *plugin) cmdResume(e *bot.Cmd) {
+	ids, err := parseIds(e.Args)
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "usage: !resume <id>")
+		return
+	}
+	if err := p.conn.Start(e.Ctx, ids); err != nil {
+		e.Bot.Privmsg(e.Target, "error: "+err.Error())
+		return
+	}
+	e.Bot.Privmsg(e.Target, "resumed")
+}
+
+func (p *plugin) cmdStats(e *bot.Cmd) {
+	s, err := p.conn.Stats(e.Ctx)
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "error: "+err.Error())
+		return
+	}
+	e.Bot.Privmsg(e.Target, s.String())
+}
+
+func (p *plugin) cmdFiles(e *bot.Cmd) {
+	ids, err := parseIds(e.Args)
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "usage: !files <id>")
+		return
+	}
+	torrents, err := p.conn.Get(e.Ctx, ids)
+	if err != nil {
+		e.Bot.Privmsg(e.Target, "error: "+err.Error())
+		return
+	}
+	var lines []string
+	for _, t := range torrents {
+		for _, f := range t.Files {
+			lines = append(lines, fmt.Sprintf("[%d] %s (%d/%d bytes)", t.Id,
+				f.Name, f.BytesCompleted, f.Length))
+		}
+	}
+	if len(lines) == 0 {
+		e.Bot.Privmsg(e.Target, "no files")
+		return
+	}
+	paginate(e, lines)
+}
+
+// parseIds parses a list of numeric torrent ids from command arguments.
+// Transmission's torrent-get/remove/start/stop also accept hash strings as
+// ids, but only numeric ids are supported for now.
+func parseIds(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no id given")
+	}
+	ids := make([]int, 0, len(args))
+	for _, a := range args {
+		id, err := strconv.Atoi(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", a)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// paginate sends lines to e.Target, one IRC line per entry, wrapping long
+// entries so no line exceeds maxLineLen.
+func paginate(e *bot.Cmd, lines []string) {
+	for _, line := range lines {
+		for len(line) > maxLineLen {
+			e.Bot.Privmsg(e.Target, line[:maxLineLen])
+			line = line[maxLineLen:]
+		}
+		e.Bot.Privmsg(e.Target, line)
+	}
+}
+
+// statusString converts a Transmission torrent status code to a short
+// human-readable string.
+func statusString(status int) string {
+	switch status {
+	case 0:
+		return "stopped"
+	case 1:
+		return "queued"
+	case 2:
+		return "checking"
+	case 3:
+		return "queued (to download)"
+	case 4:
+		return "downloading"
+	case 5:
+		return "queued to seed"
+	case 6:
+		return "seeding"
+	default:
+		return strings.TrimSpace(fmt.Sprintf("unknown(%d)", status))
+	}
+}